@@ -7,24 +7,36 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
-	"html"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ecetinerdem/gator/internal/database"
+	"github.com/ecetinerdem/gator/internal/feedparser"
+	"github.com/ecetinerdem/gator/internal/notify"
+	"github.com/ecetinerdem/gator/internal/websub"
 	"github.com/google/uuid"
 )
 
+const fetchTimeout = 10 * time.Second
+
+// websubRenewalWindow is how far ahead of a lease's expiry agg attempts to
+// resubscribe, so a slow hub response doesn't let the lease lapse.
+const websubRenewalWindow = 24 * time.Hour
+
 const configFilename = ".gatorconfig.json"
 
 type Config struct {
-	DBURL           string `json:"db_url"`
-	CurrentUserName string `json:"current_user_name"`
+	DBURL           string              `json:"db_url"`
+	CurrentUserName string              `json:"current_user_name"`
+	WebSubPort      int                 `json:"websub_port"`
+	WebSubCallback  string              `json:"websub_callback_base"`
+	Notifications   []notify.SinkConfig `json:"notifications"`
 }
 
 func (cfg *Config) SetUser(userName string) error {
@@ -88,8 +100,10 @@ func write(cfg Config) error {
 }
 
 type State struct {
-	Cfg *Config
-	DB  *database.Queries
+	Cfg       *Config
+	DB        *database.Queries
+	WebSub    *websub.Handler
+	Notifiers []notify.Notifier
 }
 
 type Command struct {
@@ -199,7 +213,19 @@ func (c *Commands) Register(Name string, f func(*State, Command) error) {
 	c.MapC[Name] = f
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*database.RSSFeed, error) {
+// fetchedFeed is the result of fetching a feed URL, including the cache
+// validators the server returned so the next fetch can be conditional.
+type fetchedFeed struct {
+	Feed         *feedparser.ParsedFeed
+	NotModified  bool
+	ETag         string
+	LastModified string
+}
+
+// fetchFeed fetches feedURL, sending If-None-Match/If-Modified-Since headers
+// when etag/lastModified are known. A 304 response is treated as a no-op
+// success with NotModified set and Feed left nil.
+func fetchFeed(ctx context.Context, feedURL, etag, lastModified string) (*fetchedFeed, error) {
 	request, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
 
 	if err != nil {
@@ -207,6 +233,12 @@ func fetchFeed(ctx context.Context, feedURL string) (*database.RSSFeed, error) {
 	}
 
 	request.Header.Set("User-Agent", "gator")
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		request.Header.Set("If-Modified-Since", lastModified)
+	}
 
 	client := &http.Client{}
 	resp, err := client.Do(request)
@@ -215,29 +247,29 @@ func fetchFeed(ctx context.Context, feedURL string) (*database.RSSFeed, error) {
 	}
 	defer resp.Body.Close()
 
-	data, err := io.ReadAll(resp.Body)
-
-	if err != nil {
-		return nil, err
+	result := &fetchedFeed{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
 	}
 
-	var feed database.RSSFeed
+	if resp.StatusCode == http.StatusNotModified {
+		result.NotModified = true
+		return result, nil
+	}
 
-	err = xml.Unmarshal(data, &feed)
+	data, err := io.ReadAll(resp.Body)
 
 	if err != nil {
 		return nil, err
 	}
 
-	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
-	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
-
-	for i := range feed.Channel.Item {
-		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
-		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
+	feed, err := feedparser.Parse(data)
+	if err != nil {
+		return nil, err
 	}
 
-	return &feed, nil
+	result.Feed = feed
+	return result, nil
 }
 
 func AddFeedHandler(s *State, cmd Command, user database.User) error {
@@ -369,6 +401,43 @@ func UnfollowHandler(s *State, cmd Command, user database.User) error {
 	return nil
 }
 
+// DeleteFeedHandler removes a feed the current user owns. If this process's
+// websub handler tracks a subscription for the feed, the hub is told to stop
+// pushing to it first. Subscriptions are held in memory only, so one made by
+// a separate agg process is not known here and no unsubscribe is sent for it.
+func DeleteFeedHandler(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("usage: deletefeed <url>")
+	}
+
+	url := cmd.Args[0]
+
+	ctx := context.Background()
+
+	feed, err := s.DB.GetFeedByURL(ctx, url)
+	if err != nil {
+		return fmt.Errorf("failed to get feed: %w", err)
+	}
+
+	if feed.UserID != user.ID {
+		return fmt.Errorf("only %s can delete feed %s", user.Name, feed.Name)
+	}
+
+	if s.WebSub != nil {
+		if err := s.WebSub.Unsubscribe(ctx, feed.ID); err != nil {
+			fmt.Printf("couldn't unsubscribe from websub hub for %s: %v\n", feed.Name, err)
+		}
+	}
+
+	if err := s.DB.DeleteFeed(ctx, feed.ID); err != nil {
+		return fmt.Errorf("failed to delete feed: %w", err)
+	}
+
+	fmt.Printf("Feed %s deleted\n", feed.Name)
+
+	return nil
+}
+
 func FollowingHandler(s *State, cmd Command, user database.User) error {
 	// Get all feed follows for user (no need to get user anymore!)
 	feedFollows, err := s.DB.GetFeedFollowsForUser(context.Background(), user.ID)
@@ -391,7 +460,7 @@ func FollowingHandler(s *State, cmd Command, user database.User) error {
 
 func AggHandler(s *State, cmd Command) error {
 	if len(cmd.Args) < 1 {
-		return errors.New("usage: agg <time_between_reqs>")
+		return errors.New("usage: agg <time_between_reqs> [concurrency]")
 	}
 
 	timeBetweenRequests, err := time.ParseDuration(cmd.Args[0])
@@ -399,75 +468,221 @@ func AggHandler(s *State, cmd Command) error {
 		return fmt.Errorf("invalid duration: %w", err)
 	}
 
-	fmt.Printf("Collecting feeds every %s\n", timeBetweenRequests)
+	concurrency := 1
+	if len(cmd.Args) > 1 {
+		concurrency, err = strconv.Atoi(cmd.Args[1])
+		if err != nil || concurrency < 1 {
+			return fmt.Errorf("invalid concurrency: %s", cmd.Args[1])
+		}
+	}
+
+	fmt.Printf("Collecting feeds every %s with %d worker(s)\n", timeBetweenRequests, concurrency)
 
 	ticker := time.NewTicker(timeBetweenRequests)
 	defer ticker.Stop()
 
 	// Run immediately, then on each tick
 	for ; ; <-ticker.C {
-		err := scrapeFeeds(s)
-		if err != nil {
-			fmt.Printf("Error scraping feeds: %v\n", err)
+		if s.WebSub != nil {
+			s.WebSub.RenewExpiring(context.Background(), websubRenewalWindow)
 		}
+		scrapeFeeds(s, timeBetweenRequests, concurrency)
 	}
 }
 
-func scrapeFeeds(s *State) error {
-	// Get the next feed to fetch
-	feed, err := s.DB.GetNextFeedToFetch(context.Background())
+// feedStat records the outcome of fetching a single feed during one scrape cycle.
+type feedStat struct {
+	name string
+	err  error
+}
+
+// scrapeFeeds fans out feeds due for a refresh (those not fetched within
+// interval) across concurrency workers. At most concurrency*10 feeds are
+// taken per cycle, least recently fetched first; any further due feeds wait
+// for a later cycle. A failure on one feed is recorded and
+// never blocks the others, and MarkFeedFetched is always called first so a
+// feed that repeatedly fails to parse doesn't monopolize the queue.
+func scrapeFeeds(s *State, interval time.Duration, concurrency int) {
+	feeds, err := s.DB.GetFeedsToFetch(context.Background(), database.GetFeedsToFetchParams{
+		LastFetchedAt: sql.NullTime{Time: time.Now().Add(-interval), Valid: true},
+		Limit:         int32(concurrency * 10),
+	})
 	if err != nil {
-		return fmt.Errorf("couldn't get next feed to fetch: %w", err)
+		fmt.Printf("couldn't get feeds to fetch: %v\n", err)
+		return
 	}
 
-	// Mark it as fetched
-	err = s.DB.MarkFeedFetched(context.Background(), feed.ID)
+	if len(feeds) == 0 {
+		return
+	}
+
+	feedCh := make(chan database.Feed)
+	statCh := make(chan feedStat)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for feed := range feedCh {
+				statCh <- scrapeFeed(s, feed)
+			}
+		}()
+	}
+
+	go func() {
+		for _, feed := range feeds {
+			feedCh <- feed
+		}
+		close(feedCh)
+		wg.Wait()
+		close(statCh)
+	}()
+
+	succeeded, failed := 0, 0
+	for stat := range statCh {
+		if stat.err != nil {
+			failed++
+			fmt.Printf("couldn't scrape feed %s: %v\n", stat.name, stat.err)
+			continue
+		}
+		succeeded++
+	}
+
+	fmt.Printf("Scrape cycle done: %d/%d feeds succeeded\n", succeeded, succeeded+failed)
+}
+
+// scrapeFeed fetches and stores the posts for a single feed. It always marks
+// the feed as fetched, even when the fetch or parse fails, so that a broken
+// feed gets rotated out of the queue instead of being retried forever.
+func scrapeFeed(s *State, feed database.Feed) feedStat {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	err := s.DB.MarkFeedFetched(ctx, feed.ID)
 	if err != nil {
-		return fmt.Errorf("couldn't mark feed as fetched: %w", err)
+		return feedStat{name: feed.Name, err: fmt.Errorf("couldn't mark feed as fetched: %w", err)}
 	}
 
-	// Fetch the feed
-	rssFeed, err := fetchFeed(context.Background(), feed.Url)
+	fetched, err := fetchFeed(ctx, feed.Url, feed.Etag.String, feed.LastModified.String)
 	if err != nil {
-		return fmt.Errorf("couldn't fetch feed %s: %w", feed.Name, err)
+		return feedStat{name: feed.Name, err: fmt.Errorf("couldn't fetch feed: %w", err)}
 	}
 
-	fmt.Printf("Feed %s collected, %d posts found\n", feed.Name, len(rssFeed.Channel.Item))
+	if fetched.ETag != "" || fetched.LastModified != "" {
+		// A server commonly echoes back only the validator it actually
+		// supports, so default each column to its previous value and only
+		// overwrite the one(s) the response refreshed.
+		etag := feed.Etag
+		if fetched.ETag != "" {
+			etag = sql.NullString{String: fetched.ETag, Valid: true}
+		}
+		lastModified := feed.LastModified
+		if fetched.LastModified != "" {
+			lastModified = sql.NullString{String: fetched.LastModified, Valid: true}
+		}
 
-	// Save each post to the database
-	for _, item := range rssFeed.Channel.Item {
-		// Parse the published date
-		publishedAt, err := parseDate(item.PubDate)
-		if err != nil {
-			fmt.Printf("couldn't parse date %s: %v\n", item.PubDate, err)
-			continue
+		if err := s.DB.UpdateFeedCacheHeaders(ctx, database.UpdateFeedCacheHeadersParams{
+			ID:           feed.ID,
+			Etag:         etag,
+			LastModified: lastModified,
+		}); err != nil {
+			fmt.Printf("couldn't persist cache headers for %s: %v\n", feed.Name, err)
 		}
+	}
 
-		// Create the post
-		_, err = s.DB.CreatePost(context.Background(), database.CreatePostParams{
-			ID:        uuid.New(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-			Title:     item.Title,
-			Url:       item.Link,
-			Description: sql.NullString{
-				String: item.Description,
-				Valid:  item.Description != "",
-			},
-			PublishedAt: publishedAt,
-			FeedID:      feed.ID,
-		})
-		if err != nil {
-			// Check if it's a duplicate URL error
-			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-				continue // Skip duplicate posts
-			}
+	if fetched.NotModified {
+		fmt.Printf("Feed %s not modified since last fetch\n", feed.Name)
+		return feedStat{name: feed.Name}
+	}
+
+	rssFeed := fetched.Feed
+
+	fmt.Printf("Feed %s collected, %d posts found\n", feed.Name, len(rssFeed.Items))
+
+	for _, item := range rssFeed.Items {
+		if _, err := storePost(ctx, s, feed.ID, feed.Name, item); err != nil {
 			fmt.Printf("couldn't create post: %v\n", err)
-			continue
 		}
 	}
 
-	return nil
+	if s.WebSub != nil && rssFeed.HubURL != "" {
+		topic := rssFeed.SelfURL
+		if topic == "" {
+			topic = feed.Url
+		}
+		if err := s.WebSub.EnsureSubscribed(ctx, feed.ID, rssFeed.HubURL, topic); err != nil {
+			fmt.Printf("couldn't subscribe to websub hub for %s: %v\n", feed.Name, err)
+		}
+	}
+
+	return feedStat{name: feed.Name}
+}
+
+// storePost parses and inserts a single feed item, matching CreatePost's
+// existing duplicate-URL short-circuit. A bad publish date or a duplicate
+// post is not treated as an error, since both are routine. A newly inserted
+// post is passed to every configured notifier before returning, and the
+// result reports whether a new post was actually inserted.
+func storePost(ctx context.Context, s *State, feedID uuid.UUID, feedName string, item feedparser.ParsedItem) (bool, error) {
+	publishedAt, err := parseDate(item.PublishedAt)
+	if err != nil {
+		fmt.Printf("couldn't parse date %s: %v\n", item.PublishedAt, err)
+		return false, nil
+	}
+
+	_, err = s.DB.CreatePost(ctx, database.CreatePostParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Title:     item.Title,
+		Url:       item.Link,
+		Description: sql.NullString{
+			String: item.Description,
+			Valid:  item.Description != "",
+		},
+		PublishedAt: publishedAt,
+		FeedID:      feedID,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return false, nil // Skip duplicate posts
+		}
+		return false, err
+	}
+
+	notifyAll(ctx, s, notify.Post{Title: item.Title, URL: item.Link, FeedName: feedName})
+
+	return true, nil
+}
+
+// notifyAll fans a newly inserted post out to every configured notification
+// sink, logging rather than failing on an individual sink's error.
+func notifyAll(ctx context.Context, s *State, post notify.Post) {
+	for _, notifier := range s.Notifiers {
+		if err := notifier.Notify(ctx, post); err != nil {
+			fmt.Printf("couldn't deliver notification: %v\n", err)
+		}
+	}
+}
+
+// HandleWebSubNotification parses a pushed feed body and stores any new
+// posts, mirroring the polling path in scrapeFeed.
+func HandleWebSubNotification(s *State) websub.NotificationHandler {
+	return func(ctx context.Context, feedID uuid.UUID, body []byte) error {
+		parsed, err := feedparser.Parse(body)
+		if err != nil {
+			return fmt.Errorf("couldn't parse pushed feed body: %w", err)
+		}
+
+		for _, item := range parsed.Items {
+			if _, err := storePost(ctx, s, feedID, parsed.Title, item); err != nil {
+				fmt.Printf("couldn't create post from websub push: %v\n", err)
+			}
+		}
+
+		return nil
+	}
 }
 
 func parseDate(dateStr string) (time.Time, error) {
@@ -536,3 +751,237 @@ func BrowseHandler(s *State, cmd Command, user database.User) error {
 
 	return nil
 }
+
+// SearchHandler runs a full-text search over the current user's followed
+// posts: `search <query> [--feed=name] [--since=2024-01-01]`.
+func SearchHandler(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("usage: search <query> [--feed=name] [--since=2006-01-02]")
+	}
+
+	var terms []string
+	var feedName string
+	var since sql.NullTime
+
+	for _, arg := range cmd.Args {
+		switch {
+		case strings.HasPrefix(arg, "--feed="):
+			feedName = strings.TrimPrefix(arg, "--feed=")
+		case strings.HasPrefix(arg, "--since="):
+			parsed, err := time.Parse("2006-01-02", strings.TrimPrefix(arg, "--since="))
+			if err != nil {
+				return fmt.Errorf("invalid --since date: %w", err)
+			}
+			since = sql.NullTime{Time: parsed, Valid: true}
+		default:
+			terms = append(terms, arg)
+		}
+	}
+
+	query := strings.Join(terms, " ")
+	if query == "" {
+		return errors.New("usage: search <query> [--feed=name] [--since=2006-01-02]")
+	}
+
+	results, err := s.DB.SearchPosts(context.Background(), database.SearchPostsParams{
+		Query:       query,
+		UserID:      user.ID,
+		FeedName:    feedName,
+		Since:       since,
+		ResultLimit: 10,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't search posts: %w", err)
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No matching posts found")
+		return nil
+	}
+
+	fmt.Printf("Found %d matching post(s):\n\n", len(results))
+	for _, result := range results {
+		fmt.Printf("Title: %s\n", result.Title)
+		fmt.Printf("URL: %s\n", result.Url)
+		fmt.Printf("Snippet: %s\n", result.Snippet)
+		fmt.Printf("Published: %s\n", result.PublishedAt.Format("2006-01-02 15:04:05"))
+		fmt.Println("=====================================")
+	}
+
+	return nil
+}
+
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Body    opmlBody `xml:"body"`
+}
+
+type opmlBody struct {
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// opmlFeed is a flattened (title, url, category) entry found anywhere in an
+// OPML document, where category is the name of the nearest enclosing folder
+// outline, if any.
+type opmlFeed struct {
+	title    string
+	url      string
+	category string
+}
+
+func flattenOPMLOutlines(outlines []opmlOutline, category string) []opmlFeed {
+	var feeds []opmlFeed
+	for _, outline := range outlines {
+		if outline.XMLURL != "" {
+			title := outline.Title
+			if title == "" {
+				title = outline.Text
+			}
+			feeds = append(feeds, opmlFeed{title: title, url: outline.XMLURL, category: category})
+			continue
+		}
+
+		folder := outline.Title
+		if folder == "" {
+			folder = outline.Text
+		}
+		feeds = append(feeds, flattenOPMLOutlines(outline.Outlines, folder)...)
+	}
+	return feeds
+}
+
+func ImportOPMLHandler(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("usage: importopml <path>")
+	}
+
+	data, err := os.ReadFile(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't read OPML file: %w", err)
+	}
+
+	var doc opmlDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("couldn't parse OPML file: %w", err)
+	}
+
+	entries := flattenOPMLOutlines(doc.Body.Outlines, "")
+
+	imported, followed := 0, 0
+	for _, entry := range entries {
+		ctx := context.Background()
+
+		feed, err := s.DB.GetFeedByURL(ctx, entry.url)
+		if err != nil {
+			feed, err = s.DB.CreateFeedWithCategory(ctx, database.CreateFeedWithCategoryParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				Name:      entry.title,
+				Url:       entry.url,
+				UserID:    user.ID,
+				Category:  sql.NullString{String: entry.category, Valid: entry.category != ""},
+			})
+			if err != nil {
+				fmt.Printf("couldn't create feed %s: %v\n", entry.url, err)
+				continue
+			}
+			imported++
+		}
+
+		_, err = s.DB.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+		})
+		if err != nil {
+			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+				continue // Already following
+			}
+			fmt.Printf("couldn't follow feed %s: %v\n", entry.url, err)
+			continue
+		}
+		followed++
+	}
+
+	fmt.Printf("Imported %d new feed(s), now following %d feed(s) from %s\n", imported, followed, cmd.Args[0])
+
+	return nil
+}
+
+// buildOPMLOutlines nests followed feeds under a folder outline per
+// category, preserving the category each feed was imported (or created)
+// with, so a single level of folders round-trips through import and export.
+// Deeper nesting is not kept: a feed comes back under its innermost folder.
+func buildOPMLOutlines(follows []database.ListFeedFollowsForExportRow) []opmlOutline {
+	var uncategorized []opmlOutline
+	var categories []string
+	byCategory := make(map[string][]opmlOutline)
+
+	for _, follow := range follows {
+		leaf := opmlOutline{
+			Text:   follow.FeedName,
+			Title:  follow.FeedName,
+			XMLURL: follow.FeedUrl,
+		}
+
+		if !follow.FeedCategory.Valid || follow.FeedCategory.String == "" {
+			uncategorized = append(uncategorized, leaf)
+			continue
+		}
+
+		category := follow.FeedCategory.String
+		if _, seen := byCategory[category]; !seen {
+			categories = append(categories, category)
+		}
+		byCategory[category] = append(byCategory[category], leaf)
+	}
+
+	outlines := make([]opmlOutline, 0, len(categories)+len(uncategorized))
+	for _, category := range categories {
+		outlines = append(outlines, opmlOutline{
+			Text:     category,
+			Title:    category,
+			Outlines: byCategory[category],
+		})
+	}
+	outlines = append(outlines, uncategorized...)
+
+	return outlines
+}
+
+func ExportOPMLHandler(s *State, cmd Command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("usage: exportopml <path>")
+	}
+
+	follows, err := s.DB.ListFeedFollowsForExport(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't list followed feeds: %w", err)
+	}
+
+	doc := opmlDocument{Version: "2.0", Body: opmlBody{Outlines: buildOPMLOutlines(follows)}}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't build OPML document: %w", err)
+	}
+
+	if err := os.WriteFile(cmd.Args[0], append([]byte(xml.Header), out...), 0644); err != nil {
+		return fmt.Errorf("couldn't write OPML file: %w", err)
+	}
+
+	fmt.Printf("Exported %d feed(s) to %s\n", len(follows), cmd.Args[0])
+
+	return nil
+}
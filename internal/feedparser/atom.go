@@ -0,0 +1,90 @@
+package feedparser
+
+import "encoding/xml"
+
+type atomFeed struct {
+	Title   string      `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title     string       `xml:"title"`
+	Summary   string       `xml:"summary"`
+	Content   string       `xml:"content"`
+	Updated   string       `xml:"updated"`
+	Published string       `xml:"published"`
+	Links     []atomLink   `xml:"link"`
+	Authors   []atomAuthor `xml:"author"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+func parseAtom(data []byte) (*ParsedFeed, error) {
+	var feed atomFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, err
+	}
+
+	parsed := &ParsedFeed{
+		Title:   feed.Title,
+		Items:   make([]ParsedItem, 0, len(feed.Entries)),
+		HubURL:  linkByRel(feed.Links, "hub"),
+		SelfURL: linkByRel(feed.Links, "self"),
+	}
+
+	for _, entry := range feed.Entries {
+		item := ParsedItem{
+			Title:       entry.Title,
+			Link:        alternateLink(entry.Links),
+			Description: entry.Summary,
+			PublishedAt: entry.Published,
+		}
+		if item.Description == "" {
+			item.Description = entry.Content
+		}
+		if item.PublishedAt == "" {
+			item.PublishedAt = entry.Updated
+		}
+		for _, author := range entry.Authors {
+			if author.Name != "" {
+				item.Authors = append(item.Authors, author.Name)
+			}
+		}
+		parsed.Items = append(parsed.Items, item)
+	}
+
+	return parsed, nil
+}
+
+// alternateLink picks the entry's first rel="alternate" link, counting a link
+// with no rel as alternate (Atom's default), and falls back to the first link
+// when no link qualifies.
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "alternate" || link.Rel == "" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+// linkByRel returns the href of the first link with the given rel attribute.
+func linkByRel(links []atomLink, rel string) string {
+	for _, link := range links {
+		if link.Rel == rel {
+			return link.Href
+		}
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+// Package feedparser normalizes RSS 2.0, Atom 1.0 and RDF/RSS 1.0 feeds into
+// a single ParsedFeed shape so callers don't need to know which format a
+// given feed URL happens to serve.
+package feedparser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+)
+
+// ParsedFeed is the format-agnostic result of parsing a feed document.
+type ParsedFeed struct {
+	Title       string
+	Description string
+	Items       []ParsedItem
+	// HubURL and SelfURL come from a rel="hub"/rel="self" link in the feed,
+	// if present, and are used to set up a WebSub push subscription.
+	HubURL  string
+	SelfURL string
+}
+
+// ParsedItem is a single entry normalized out of an RSS, Atom or RDF feed.
+type ParsedItem struct {
+	Title       string
+	Link        string
+	Description string
+	PublishedAt string
+	Authors     []string
+}
+
+type rootProbe struct {
+	XMLName xml.Name
+}
+
+// Parse sniffs the root element of data and decodes it with the matching
+// format-specific parser, returning a normalized ParsedFeed with HTML
+// entities in titles/descriptions already unescaped.
+func Parse(data []byte) (*ParsedFeed, error) {
+	var probe rootProbe
+	if err := xml.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("couldn't determine feed format: %w", err)
+	}
+
+	var feed *ParsedFeed
+	var err error
+
+	switch probe.XMLName.Local {
+	case "rss":
+		feed, err = parseRSS(data)
+	case "feed":
+		feed, err = parseAtom(data)
+	case "RDF":
+		feed, err = parseRDF(data)
+	default:
+		return nil, fmt.Errorf("unrecognized feed format: root element %q", probe.XMLName.Local)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	unescape(feed)
+	return feed, nil
+}
+
+// unescape decodes HTML entities in a feed's and its items' text fields, so
+// every insertion path (polling or WebSub push) sees the same unescaped data.
+func unescape(feed *ParsedFeed) {
+	feed.Title = html.UnescapeString(feed.Title)
+	feed.Description = html.UnescapeString(feed.Description)
+
+	for i := range feed.Items {
+		feed.Items[i].Title = html.UnescapeString(feed.Items[i].Title)
+		feed.Items[i].Description = html.UnescapeString(feed.Items[i].Description)
+	}
+}
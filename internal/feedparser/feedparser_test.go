@@ -0,0 +1,159 @@
+package feedparser
+
+import "testing"
+
+func TestParseRSS(t *testing.T) {
+	data := []byte(`<?xml version="1.0"?>
+<rss version="2.0">
+  <channel>
+    <title>Example RSS</title>
+    <description>An RSS 2.0 feed</description>
+    <item>
+      <title>First post</title>
+      <link>https://example.com/1</link>
+      <description>Body</description>
+      <pubDate>Mon, 02 Jan 2006 15:04:05 -0700</pubDate>
+    </item>
+  </channel>
+</rss>`)
+
+	feed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if feed.Title != "Example RSS" {
+		t.Errorf("Title = %q, want %q", feed.Title, "Example RSS")
+	}
+	if len(feed.Items) != 1 {
+		t.Fatalf("len(Items) = %d, want 1", len(feed.Items))
+	}
+	if feed.Items[0].Link != "https://example.com/1" {
+		t.Errorf("Items[0].Link = %q, want %q", feed.Items[0].Link, "https://example.com/1")
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	data := []byte(`<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Atom</title>
+  <link rel="hub" href="https://hub.example.com/"/>
+  <link rel="self" href="https://example.com/feed.atom"/>
+  <entry>
+    <title>First entry</title>
+    <link rel="alternate" href="https://example.com/entry-1"/>
+    <summary>A summary</summary>
+    <published>2006-01-02T15:04:05Z</published>
+    <author><name>Jane Doe</name></author>
+  </entry>
+</feed>`)
+
+	feed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if feed.HubURL != "https://hub.example.com/" {
+		t.Errorf("HubURL = %q, want %q", feed.HubURL, "https://hub.example.com/")
+	}
+	if feed.SelfURL != "https://example.com/feed.atom" {
+		t.Errorf("SelfURL = %q, want %q", feed.SelfURL, "https://example.com/feed.atom")
+	}
+
+	if len(feed.Items) != 1 {
+		t.Fatalf("len(Items) = %d, want 1", len(feed.Items))
+	}
+	item := feed.Items[0]
+	if item.Link != "https://example.com/entry-1" {
+		t.Errorf("Link = %q, want %q", item.Link, "https://example.com/entry-1")
+	}
+	if item.Description != "A summary" {
+		t.Errorf("Description = %q, want %q", item.Description, "A summary")
+	}
+	if len(item.Authors) != 1 || item.Authors[0] != "Jane Doe" {
+		t.Errorf("Authors = %v, want [Jane Doe]", item.Authors)
+	}
+}
+
+func TestParseAtomFallsBackToContentWhenSummaryMissing(t *testing.T) {
+	data := []byte(`<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Atom</title>
+  <entry>
+    <title>Entry</title>
+    <link href="https://example.com/entry-1"/>
+    <content>Full content</content>
+    <updated>2006-01-02T15:04:05Z</updated>
+  </entry>
+</feed>`)
+
+	feed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	item := feed.Items[0]
+	if item.Description != "Full content" {
+		t.Errorf("Description = %q, want %q", item.Description, "Full content")
+	}
+	if item.PublishedAt != "2006-01-02T15:04:05Z" {
+		t.Errorf("PublishedAt = %q, want updated value", item.PublishedAt)
+	}
+}
+
+func TestParseRDF(t *testing.T) {
+	data := []byte(`<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>Example RDF</title>
+    <description>An RSS 1.0 feed</description>
+  </channel>
+  <item>
+    <title>First item</title>
+    <link>https://example.com/rdf-1</link>
+    <description>Body</description>
+    <dc:date>2006-01-02T15:04:05Z</dc:date>
+  </item>
+</rdf:RDF>`)
+
+	feed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if feed.Title != "Example RDF" {
+		t.Errorf("Title = %q, want %q", feed.Title, "Example RDF")
+	}
+	if len(feed.Items) != 1 {
+		t.Fatalf("len(Items) = %d, want 1", len(feed.Items))
+	}
+	if feed.Items[0].PublishedAt != "2006-01-02T15:04:05Z" {
+		t.Errorf("PublishedAt = %q, want dc:date value", feed.Items[0].PublishedAt)
+	}
+}
+
+func TestAlternateLinkPrefersAlternateRel(t *testing.T) {
+	links := []atomLink{
+		{Href: "https://example.com/hub", Rel: "hub"},
+		{Href: "https://example.com/entry", Rel: "alternate"},
+	}
+
+	if got := alternateLink(links); got != "https://example.com/entry" {
+		t.Errorf("alternateLink = %q, want %q", got, "https://example.com/entry")
+	}
+}
+
+func TestAlternateLinkFallsBackToFirstWhenNoRel(t *testing.T) {
+	links := []atomLink{{Href: "https://example.com/only-link"}}
+
+	if got := alternateLink(links); got != "https://example.com/only-link" {
+		t.Errorf("alternateLink = %q, want %q", got, "https://example.com/only-link")
+	}
+}
+
+func TestParseUnrecognizedFormat(t *testing.T) {
+	_, err := Parse([]byte(`<?xml version="1.0"?><unknown></unknown>`))
+	if err == nil {
+		t.Fatal("expected an error for an unrecognized root element")
+	}
+}
@@ -0,0 +1,44 @@
+package feedparser
+
+import "encoding/xml"
+
+// rdfFeed models RDF Site Summary (RSS 1.0) documents, where items are
+// siblings of <channel> rather than nested inside it.
+type rdfFeed struct {
+	Channel struct {
+		Title       string `xml:"title"`
+		Description string `xml:"description"`
+	} `xml:"channel"`
+	Items []rdfItem `xml:"item"`
+}
+
+type rdfItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+func parseRDF(data []byte) (*ParsedFeed, error) {
+	var feed rdfFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, err
+	}
+
+	parsed := &ParsedFeed{
+		Title:       feed.Channel.Title,
+		Description: feed.Channel.Description,
+		Items:       make([]ParsedItem, 0, len(feed.Items)),
+	}
+
+	for _, item := range feed.Items {
+		parsed.Items = append(parsed.Items, ParsedItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			PublishedAt: item.Date,
+		})
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,50 @@
+package feedparser
+
+import "encoding/xml"
+
+type rssFeed struct {
+	Channel struct {
+		Title       string     `xml:"title"`
+		Description string     `xml:"description"`
+		AtomLinks   []atomLink `xml:"http://www.w3.org/2005/Atom link"`
+		Item        []rssItem  `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	PubDate     string `xml:"pubDate"`
+	Author      string `xml:"author"`
+}
+
+func parseRSS(data []byte) (*ParsedFeed, error) {
+	var feed rssFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, err
+	}
+
+	parsed := &ParsedFeed{
+		Title:       feed.Channel.Title,
+		Description: feed.Channel.Description,
+		Items:       make([]ParsedItem, 0, len(feed.Channel.Item)),
+		HubURL:      linkByRel(feed.Channel.AtomLinks, "hub"),
+		SelfURL:     linkByRel(feed.Channel.AtomLinks, "self"),
+	}
+
+	for _, item := range feed.Channel.Item {
+		parsedItem := ParsedItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			PublishedAt: item.PubDate,
+		}
+		if item.Author != "" {
+			parsedItem.Authors = []string{item.Author}
+		}
+		parsed.Items = append(parsed.Items, parsedItem)
+	}
+
+	return parsed, nil
+}
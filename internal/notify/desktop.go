@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// DesktopNotifier shows a local desktop notification via notify-send on
+// Linux or osascript on macOS.
+type DesktopNotifier struct{}
+
+func (n *DesktopNotifier) Notify(ctx context.Context, post Post) error {
+	body := fmt.Sprintf("%s\n%s", post.FeedName, post.URL)
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", body, post.Title)
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	default:
+		cmd = exec.CommandContext(ctx, "notify-send", post.Title, body)
+	}
+
+	return cmd.Run()
+}
@@ -0,0 +1,138 @@
+// Package notify delivers newly aggregated posts to pluggable sinks
+// (desktop notifications, webhooks, ntfy/Gotify push) configured per sink
+// in .gatorconfig.json, each with its own feed name and keyword filters.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Post is the subset of a new post a sink needs in order to notify about it.
+type Post struct {
+	Title    string
+	URL      string
+	FeedName string
+}
+
+// Notifier delivers a single notification for a newly inserted post.
+type Notifier interface {
+	Notify(ctx context.Context, post Post) error
+}
+
+// SinkConfig describes one entry of the "notifications" array in
+// .gatorconfig.json.
+type SinkConfig struct {
+	Type        string   `json:"type"`
+	URL         string   `json:"url,omitempty"`
+	FeedPattern string   `json:"feed_pattern,omitempty"`
+	Include     []string `json:"include,omitempty"`
+	Exclude     []string `json:"exclude,omitempty"`
+}
+
+// BuildAll constructs a Notifier for every configured sink, skipping (and
+// reporting) any sink with an unknown type rather than failing the whole set.
+func BuildAll(configs []SinkConfig) []Notifier {
+	notifiers := make([]Notifier, 0, len(configs))
+	for _, cfg := range configs {
+		notifier, err := build(cfg)
+		if err != nil {
+			fmt.Printf("couldn't configure notification sink: %v\n", err)
+			continue
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	return notifiers
+}
+
+func build(cfg SinkConfig) (Notifier, error) {
+	var sink Notifier
+
+	switch cfg.Type {
+	case "desktop":
+		sink = &DesktopNotifier{}
+	case "webhook":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webhook sink missing url")
+		}
+		sink = &WebhookNotifier{URL: cfg.URL}
+	case "ntfy":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("ntfy sink missing url")
+		}
+		sink = &NtfyNotifier{URL: cfg.URL}
+	default:
+		return nil, fmt.Errorf("unknown notification sink type %q", cfg.Type)
+	}
+
+	filter, err := newFilter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &filteredNotifier{filter: filter, sink: sink}, nil
+}
+
+// filter decides whether a post is relevant to a sink, based on a feed name
+// regex and keyword include/exclude lists.
+type filter struct {
+	feedPattern *regexp.Regexp
+	include     []string
+	exclude     []string
+}
+
+func newFilter(cfg SinkConfig) (filter, error) {
+	f := filter{include: cfg.Include, exclude: cfg.Exclude}
+
+	if cfg.FeedPattern != "" {
+		pattern, err := regexp.Compile(cfg.FeedPattern)
+		if err != nil {
+			return filter{}, fmt.Errorf("invalid feed_pattern %q: %w", cfg.FeedPattern, err)
+		}
+		f.feedPattern = pattern
+	}
+
+	return f, nil
+}
+
+func (f filter) matches(post Post) bool {
+	if f.feedPattern != nil && !f.feedPattern.MatchString(post.FeedName) {
+		return false
+	}
+
+	if len(f.include) > 0 && !containsAny(post.Title, f.include) {
+		return false
+	}
+
+	if containsAny(post.Title, f.exclude) {
+		return false
+	}
+
+	return true
+}
+
+func containsAny(text string, keywords []string) bool {
+	lower := strings.ToLower(text)
+	for _, keyword := range keywords {
+		if strings.Contains(lower, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// filteredNotifier wraps a Notifier so that Notify only reaches the
+// underlying sink when the post passes the configured filter.
+type filteredNotifier struct {
+	filter filter
+	sink   Notifier
+}
+
+func (f *filteredNotifier) Notify(ctx context.Context, post Post) error {
+	if !f.filter.matches(post) {
+		return nil
+	}
+	return f.sink.Notify(ctx, post)
+}
@@ -0,0 +1,57 @@
+package notify
+
+import "testing"
+
+func TestFilterMatchesFeedPattern(t *testing.T) {
+	f, err := newFilter(SinkConfig{FeedPattern: "^Hacker"})
+	if err != nil {
+		t.Fatalf("newFilter returned error: %v", err)
+	}
+
+	if !f.matches(Post{FeedName: "Hacker News", Title: "Anything"}) {
+		t.Error("expected a matching feed name to pass the filter")
+	}
+	if f.matches(Post{FeedName: "Lobsters", Title: "Anything"}) {
+		t.Error("expected a non-matching feed name to fail the filter")
+	}
+}
+
+func TestFilterInvalidFeedPattern(t *testing.T) {
+	if _, err := newFilter(SinkConfig{FeedPattern: "("}); err == nil {
+		t.Fatal("expected an error for an invalid regex")
+	}
+}
+
+func TestFilterIncludeKeywords(t *testing.T) {
+	f, err := newFilter(SinkConfig{Include: []string{"release"}})
+	if err != nil {
+		t.Fatalf("newFilter returned error: %v", err)
+	}
+
+	if !f.matches(Post{Title: "New Release out now"}) {
+		t.Error("expected a title containing an include keyword to pass")
+	}
+	if f.matches(Post{Title: "Unrelated post"}) {
+		t.Error("expected a title missing every include keyword to fail")
+	}
+}
+
+func TestFilterExcludeKeywords(t *testing.T) {
+	f, err := newFilter(SinkConfig{Exclude: []string{"spoiler"}})
+	if err != nil {
+		t.Fatalf("newFilter returned error: %v", err)
+	}
+
+	if f.matches(Post{Title: "Big SPOILER inside"}) {
+		t.Error("expected a title containing an exclude keyword to fail, case-insensitively")
+	}
+	if !f.matches(Post{Title: "Safe to read"}) {
+		t.Error("expected a title without exclude keywords to pass")
+	}
+}
+
+func TestBuildUnknownSinkType(t *testing.T) {
+	if _, err := build(SinkConfig{Type: "carrier-pigeon"}); err == nil {
+		t.Fatal("expected an error for an unknown sink type")
+	}
+}
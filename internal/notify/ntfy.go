@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// NtfyNotifier pushes a plain-text message to an ntfy or Gotify topic URL.
+type NtfyNotifier struct {
+	URL string
+}
+
+func (n *NtfyNotifier) Notify(ctx context.Context, post Post) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, strings.NewReader(post.URL))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", fmt.Sprintf("%s: %s", post.FeedName, post.Title))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("ntfy push returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// WebhookNotifier POSTs a JSON payload describing the post to URL.
+type WebhookNotifier struct {
+	URL string
+}
+
+type webhookPayload struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Feed  string `json:"feed"`
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, post Post) error {
+	body, err := json.Marshal(webhookPayload{Title: post.Title, URL: post.URL, Feed: post.FeedName})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,12 @@
+package websub
+
+import "strings"
+
+func lastPathSegment(path string) string {
+	path = strings.TrimSuffix(path, "/")
+	idx := strings.LastIndex(path, "/")
+	if idx < 0 {
+		return path
+	}
+	return path[idx+1:]
+}
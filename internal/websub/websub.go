@@ -0,0 +1,238 @@
+// Package websub implements the subscriber side of a PubSubHubbub/WebSub
+// handshake: subscribing to a hub for a feed's topic URL, serving the hub's
+// verification challenge, and accepting push notifications on a callback
+// server instead of polling.
+package websub
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultLeaseSeconds = 10 * 24 * 60 * 60 // 10 days
+
+// NotificationHandler processes a pushed feed body for feedID, the same way
+// a polled fetch would: parse it and store any new posts.
+type NotificationHandler func(ctx context.Context, feedID uuid.UUID, body []byte) error
+
+type subscription struct {
+	feedID      uuid.UUID
+	hubURL      string
+	topicURL    string
+	verifyToken string
+	expiresAt   time.Time
+}
+
+// Handler runs the HTTP callback server a hub delivers verification
+// challenges and push notifications to, and tracks one subscription per
+// feed so leases can be renewed before they expire.
+type Handler struct {
+	callbackBase string
+	client       *http.Client
+	onNotify     NotificationHandler
+
+	mu   sync.Mutex
+	subs map[uuid.UUID]*subscription
+}
+
+// NewHandler builds a Handler whose callback URLs are rooted at
+// callbackBase (e.g. "http://localhost:8080"), e.g. callbackBase+"/websub/<feedID>".
+func NewHandler(callbackBase string, onNotify NotificationHandler) *Handler {
+	return &Handler{
+		callbackBase: callbackBase,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		onNotify:     onNotify,
+		subs:         make(map[uuid.UUID]*subscription),
+	}
+}
+
+func (h *Handler) callbackURL(feedID uuid.UUID) string {
+	return fmt.Sprintf("%s/websub/%s", h.callbackBase, feedID)
+}
+
+// EnsureSubscribed subscribes feedID to hubURL/topicURL unless an
+// unexpired subscription is already tracked for it.
+func (h *Handler) EnsureSubscribed(ctx context.Context, feedID uuid.UUID, hubURL, topicURL string) error {
+	h.mu.Lock()
+	sub, ok := h.subs[feedID]
+	active := ok && (sub.expiresAt.IsZero() || sub.expiresAt.After(time.Now()))
+	h.mu.Unlock()
+	if active {
+		return nil
+	}
+
+	return h.Subscribe(ctx, feedID, hubURL, topicURL)
+}
+
+// Subscribe performs the hub.mode=subscribe handshake against hubURL for
+// topicURL. The hub will asynchronously GET the callback URL to verify the
+// subscription before it takes effect.
+func (h *Handler) Subscribe(ctx context.Context, feedID uuid.UUID, hubURL, topicURL string) error {
+	token, err := randomToken()
+	if err != nil {
+		return fmt.Errorf("couldn't generate verify token: %w", err)
+	}
+
+	h.mu.Lock()
+	h.subs[feedID] = &subscription{
+		feedID:      feedID,
+		hubURL:      hubURL,
+		topicURL:    topicURL,
+		verifyToken: token,
+	}
+	h.mu.Unlock()
+
+	return h.send(ctx, feedID, "subscribe")
+}
+
+// Unsubscribe tells the hub to stop delivering notifications for feedID,
+// e.g. when the feed is deleted locally. Since hub.verify=async, the hub
+// confirms with its own GET to the callback before subscription state
+// actually changes, so the tracked subscription is only removed once that
+// verification lands in handleVerify — not here.
+func (h *Handler) Unsubscribe(ctx context.Context, feedID uuid.UUID) error {
+	return h.send(ctx, feedID, "unsubscribe")
+}
+
+func (h *Handler) send(ctx context.Context, feedID uuid.UUID, mode string) error {
+	h.mu.Lock()
+	sub, ok := h.subs[feedID]
+	h.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no subscription tracked for feed %s", feedID)
+	}
+
+	form := url.Values{}
+	form.Set("hub.callback", h.callbackURL(feedID))
+	form.Set("hub.mode", mode)
+	form.Set("hub.topic", sub.topicURL)
+	form.Set("hub.verify", "async")
+	form.Set("hub.verify_token", sub.verifyToken)
+	if mode == "subscribe" {
+		form.Set("hub.lease_seconds", fmt.Sprintf("%d", defaultLeaseSeconds))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.hubURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("hub rejected %s: status %d", mode, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// RenewExpiring resubscribes any tracked subscription whose lease expires
+// within the given window. It should be called periodically alongside the
+// regular polling ticker.
+func (h *Handler) RenewExpiring(ctx context.Context, within time.Duration) {
+	deadline := time.Now().Add(within)
+
+	h.mu.Lock()
+	var due []uuid.UUID
+	for feedID, sub := range h.subs {
+		if !sub.expiresAt.IsZero() && sub.expiresAt.Before(deadline) {
+			due = append(due, feedID)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, feedID := range due {
+		if err := h.send(ctx, feedID, "subscribe"); err != nil {
+			fmt.Printf("couldn't renew websub lease for feed %s: %v\n", feedID, err)
+		}
+	}
+}
+
+// ServeHTTP handles both the hub's verification GET and the push POST with
+// the new feed body, expected at "/websub/<feedID>".
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	feedID, err := uuid.Parse(lastPathSegment(r.URL.Path))
+	if err != nil {
+		http.Error(w, "unknown feed", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.handleVerify(w, r, feedID)
+	case http.MethodPost:
+		h.handleNotify(w, r, feedID)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) handleVerify(w http.ResponseWriter, r *http.Request, feedID uuid.UUID) {
+	q := r.URL.Query()
+
+	h.mu.Lock()
+	sub, ok := h.subs[feedID]
+	h.mu.Unlock()
+	if !ok || q.Get("hub.verify_token") != sub.verifyToken {
+		http.Error(w, "verify token mismatch", http.StatusNotFound)
+		return
+	}
+
+	switch q.Get("hub.mode") {
+	case "subscribe":
+		if seconds, err := time.ParseDuration(q.Get("hub.lease_seconds") + "s"); err == nil {
+			h.mu.Lock()
+			sub.expiresAt = time.Now().Add(seconds)
+			h.mu.Unlock()
+		}
+	case "unsubscribe":
+		// Only now, with the hub's own verification in hand, is it safe to
+		// stop tracking the subscription — acking this with 2xx is what
+		// actually ends it on the hub's side too.
+		h.mu.Lock()
+		delete(h.subs, feedID)
+		h.mu.Unlock()
+	}
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, q.Get("hub.challenge"))
+}
+
+func (h *Handler) handleNotify(w http.ResponseWriter, r *http.Request, feedID uuid.UUID) {
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "couldn't read body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.onNotify(r.Context(), feedID, body); err != nil {
+		fmt.Printf("couldn't process websub push for feed %s: %v\n", feedID, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -5,10 +5,13 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 
 	"github.com/ecetinerdem/gator/internal/config"
 	"github.com/ecetinerdem/gator/internal/database"
+	"github.com/ecetinerdem/gator/internal/notify"
+	"github.com/ecetinerdem/gator/internal/websub"
 	_ "github.com/lib/pq"
 )
 
@@ -35,6 +38,21 @@ func main() {
 	dbQueries := database.New(db)
 
 	state.DB = dbQueries
+	state.Notifiers = notify.BuildAll(cfg.Notifications)
+
+	if cfg.WebSubPort != 0 {
+		handler := websub.NewHandler(cfg.WebSubCallback, config.HandleWebSubNotification(&state))
+		state.WebSub = handler
+
+		mux := http.NewServeMux()
+		mux.Handle("/websub/", handler)
+		go func() {
+			addr := fmt.Sprintf(":%d", cfg.WebSubPort)
+			if err := http.ListenAndServe(addr, mux); err != nil {
+				log.Printf("websub callback server stopped: %v", err)
+			}
+		}()
+	}
 
 	cmds := &config.Commands{
 		MapC: make(map[string]func(s *config.State, c config.Command) error),
@@ -51,7 +69,11 @@ func main() {
 	cmds.Register("follow", MiddlewareLoggedIn(config.FollowHandler))
 	cmds.Register("following", MiddlewareLoggedIn(config.FollowingHandler))
 	cmds.Register("unfollow", MiddlewareLoggedIn(config.UnfollowHandler))
+	cmds.Register("deletefeed", MiddlewareLoggedIn(config.DeleteFeedHandler))
 	cmds.Register("browse", MiddlewareLoggedIn(config.BrowseHandler))
+	cmds.Register("importopml", MiddlewareLoggedIn(config.ImportOPMLHandler))
+	cmds.Register("exportopml", MiddlewareLoggedIn(config.ExportOPMLHandler))
+	cmds.Register("search", MiddlewareLoggedIn(config.SearchHandler))
 
 	if len(os.Args) < 2 {
 		log.Fatal("no command provided")